@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"os/signal"
+	"syscall"
+
+	"go.uber.org/zap"
+
+	"tinkoff-invest-bot/internal/config"
+	"tinkoff-invest-bot/internal/engine"
+	"tinkoff-invest-bot/pkg/sdk"
+)
+
+// main поднимает микро-робота и останавливает его по SIGINT/SIGTERM,
+// дожидаясь корректного завершения через отмену корневого контекста
+func main() {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		panic(err)
+	}
+	defer logger.Sync()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	robotConfig, tradingConfig, err := config.Load()
+	if err != nil {
+		logger.Fatal("can't load config", zap.Error(err))
+	}
+
+	s, err := sdk.New(robotConfig)
+	if err != nil {
+		logger.Fatal("can't create sdk client", zap.Error(err))
+	}
+
+	r, err := engine.New(robotConfig, tradingConfig, s, logger, nil)
+	if err != nil {
+		logger.Fatal("can't create micro-robot", zap.Error(err))
+	}
+
+	r.Run(ctx)
+}
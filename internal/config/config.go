@@ -0,0 +1,40 @@
+package config
+
+// Interval таймфрейм свечей, с которым работает стратегия
+type Interval string
+
+const (
+	Interval1Min  Interval = "1m"
+	Interval5Min  Interval = "5m"
+	Interval15Min Interval = "15m"
+	Interval1Hour Interval = "1h"
+)
+
+// RobotConfig общие настройки микро-робота: доступ к Tinkoff Invest API и аккаунт,
+// от имени которого выставляются заявки
+type RobotConfig struct {
+	Token     string
+	AccountID string
+	Sandbox   bool
+}
+
+// StrategyConfig описывает, какую торговую стратегию запускать и с какими параметрами
+type StrategyConfig struct {
+	// Name имя встроенной стратегии, используется, если PluginPath не задан
+	Name string
+	// Interval таймфрейм свечей стратегии
+	Interval Interval
+	// Params произвольные параметры стратегии, специфичные для Name или плагина
+	Params map[string]any
+	// PluginPath путь к скомпилированному .so с пользовательской стратегией.
+	// Если задан, имеет приоритет над встроенной стратегией Name
+	PluginPath string
+}
+
+// TradingConfig параметры торговли одним инструментом
+type TradingConfig struct {
+	Figi           string
+	Ticker         string
+	Exchange       string
+	StrategyConfig StrategyConfig
+}
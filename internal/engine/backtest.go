@@ -0,0 +1,184 @@
+package engine
+
+import (
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+
+	"tinkoff-invest-bot/internal/config"
+	"tinkoff-invest-bot/internal/strategy"
+	"tinkoff-invest-bot/pkg/sdk"
+)
+
+// maxCandlesPageSpan максимальный диапазон одного запроса к sdk.GetCandles,
+// дальше история запрашивается постранично
+const maxCandlesPageSpan = 24 * time.Hour
+
+// TradeRecord запись о синтетической сделке, совершённой стратегией во время прогона
+type TradeRecord struct {
+	Time   time.Time
+	Signal strategy.Signal
+	Price  float64
+}
+
+// BacktestReport результат прогона стратегии по историческим свечкам
+type BacktestReport struct {
+	// PnL суммарная прибыль/убыток прогона в валюте инструмента
+	PnL float64
+	// MaxDrawdown максимальная просадка эквити за время прогона
+	MaxDrawdown float64
+	// SharpeRatio коэффициент Шарпа, посчитанный по PnL round-trip сделок в валюте инструмента
+	SharpeRatio float64
+	// WinRate доля неубыточных (PnL >= 0) round-trip сделок от их общего числа
+	WinRate float64
+	// Trades журнал синтетических сделок в хронологическом порядке
+	Trades []TradeRecord
+}
+
+// Backtester прогоняет CandlesStrategyProcessor через исторические свечки за указанный
+// период, не выставляя реальных заявок в стакан, чтобы можно было подобрать параметры
+// StrategyConfig офлайн перед запуском настоящего микро-робота
+type Backtester struct {
+	tradingConfig   *config.TradingConfig
+	tradingStrategy strategy.CandlesStrategyProcessor
+	logger          *zap.Logger
+	sdk             *sdk.SDK
+
+	from     time.Time
+	to       time.Time
+	interval sdk.CandleInterval
+}
+
+// NewBacktester создаёт новый прогон стратегии по историческим данным за [from, to]
+func NewBacktester(
+	tradingConfig *config.TradingConfig,
+	s *sdk.SDK,
+	logger *zap.Logger,
+	from, to time.Time,
+	interval sdk.CandleInterval,
+) (*Backtester, error) {
+	// Прогону по истории не нужна риск-шина портфеля — заявки не отправляются в стакан
+	tradingStrategy, err := strategy.FromConfig(tradingConfig, s, logger, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Backtester{
+		tradingConfig:   tradingConfig,
+		tradingStrategy: *tradingStrategy,
+		logger:          logger,
+		sdk:             s,
+
+		from:     from,
+		to:       to,
+		interval: interval,
+	}, nil
+}
+
+// Run постранично вычитывает историю свечек за [from, to], прогоняет её через
+// CandlesStrategyProcessor.Feed и возвращает накопленный BacktestReport
+func (b *Backtester) Run() (*BacktestReport, error) {
+	report := &BacktestReport{}
+
+	var equity, peakEquity float64
+	var position *TradeRecord
+	var wins, losses int
+	var roundTripPnLs []float64
+
+	for pageStart := b.from; pageStart.Before(b.to); pageStart = pageStart.Add(maxCandlesPageSpan) {
+		pageEnd := pageStart.Add(maxCandlesPageSpan)
+		if pageEnd.After(b.to) {
+			pageEnd = b.to
+		}
+
+		candles, _, err := b.sdk.GetCandles(b.tradingConfig.Figi, pageStart, pageEnd, b.interval)
+		if err != nil {
+			return nil, xerrors.Errorf("can't receive historic candles page [%s, %s]: %w", pageStart, pageEnd, err)
+		}
+
+		for _, c := range candles {
+			candle := strategy.HistoricCandlesToTechanCandles([]sdk.HistoricCandle{c}, sdk.IntervalToDuration(b.tradingConfig.StrategyConfig.Interval))
+			if candle.Len() == 0 {
+				continue
+			}
+			techanCandle := candle.LastCandle()
+
+			signal, err := b.tradingStrategy.Feed(*techanCandle)
+			if err != nil {
+				return nil, xerrors.Errorf("can't feed candle to strategy: %w", err)
+			}
+			if signal == strategy.SignalHold {
+				continue
+			}
+
+			price := techanCandle.ClosePrice.Float()
+			report.Trades = append(report.Trades, TradeRecord{
+				Time:   techanCandle.Period.End,
+				Signal: signal,
+				Price:  price,
+			})
+
+			if position == nil {
+				position = &TradeRecord{Time: techanCandle.Period.End, Signal: signal, Price: price}
+				continue
+			}
+
+			pnl := price - position.Price
+			if position.Signal == strategy.SignalSell {
+				pnl = -pnl
+			}
+
+			equity += pnl
+			report.PnL += pnl
+			roundTripPnLs = append(roundTripPnLs, pnl)
+			if equity > peakEquity {
+				peakEquity = equity
+			}
+			if drawdown := peakEquity - equity; drawdown > report.MaxDrawdown {
+				report.MaxDrawdown = drawdown
+			}
+			if pnl >= 0 {
+				wins++
+			} else {
+				losses++
+			}
+			position = nil
+		}
+	}
+
+	if trades := wins + losses; trades > 0 {
+		report.WinRate = float64(wins) / float64(trades)
+	}
+	report.SharpeRatio = sharpeRatio(roundTripPnLs)
+
+	return report, nil
+}
+
+// sharpeRatio считает коэффициент Шарпа по той же направленной серии round-trip PnL,
+// из которой уже посчитаны report.PnL и report.MaxDrawdown, без безрисковой ставки,
+// как грубая офлайн-оценка качества стратегии
+func sharpeRatio(roundTripPnLs []float64) float64 {
+	if len(roundTripPnLs) < 2 {
+		return 0
+	}
+
+	var mean float64
+	for _, pnl := range roundTripPnLs {
+		mean += pnl
+	}
+	mean /= float64(len(roundTripPnLs))
+
+	var variance float64
+	for _, pnl := range roundTripPnLs {
+		variance += (pnl - mean) * (pnl - mean)
+	}
+	variance /= float64(len(roundTripPnLs))
+
+	stddev := math.Sqrt(variance)
+	if stddev == 0 {
+		return 0
+	}
+	return mean / stddev
+}
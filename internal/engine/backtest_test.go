@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSharpeRatio(t *testing.T) {
+	tests := []struct {
+		name        string
+		roundTrips  []float64
+		want        float64
+		wantApprox  bool
+		wantNonZero bool
+	}{
+		{
+			name:       "empty series has no sharpe",
+			roundTrips: nil,
+			want:       0,
+		},
+		{
+			name:       "single round-trip has no sharpe",
+			roundTrips: []float64{10},
+			want:       0,
+		},
+		{
+			name:       "constant pnl has zero stddev",
+			roundTrips: []float64{5, 5, 5},
+			want:       0,
+		},
+		{
+			name:        "mixed wins and losses",
+			roundTrips:  []float64{2, -1, 3, -2},
+			wantApprox:  true,
+			wantNonZero: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sharpeRatio(tt.roundTrips)
+
+			if tt.wantApprox {
+				want := meanOverStddev(tt.roundTrips)
+				if math.Abs(got-want) > 1e-9 {
+					t.Errorf("sharpeRatio(%v) = %v, want %v", tt.roundTrips, got, want)
+				}
+				if tt.wantNonZero && got == 0 {
+					t.Errorf("sharpeRatio(%v) = 0, want non-zero", tt.roundTrips)
+				}
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("sharpeRatio(%v) = %v, want %v", tt.roundTrips, got, tt.want)
+			}
+		})
+	}
+}
+
+// meanOverStddev считает mean/stddev (стандартное отклонение по генеральной совокупности)
+// по той же формуле, что и sharpeRatio, но без её граничных случаев
+func meanOverStddev(pnls []float64) float64 {
+	var mean float64
+	for _, pnl := range pnls {
+		mean += pnl
+	}
+	mean /= float64(len(pnls))
+
+	var variance float64
+	for _, pnl := range pnls {
+		variance += (pnl - mean) * (pnl - mean)
+	}
+	variance /= float64(len(pnls))
+
+	return mean / math.Sqrt(variance)
+}
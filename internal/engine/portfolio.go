@@ -0,0 +1,239 @@
+package engine
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+
+	"tinkoff-invest-bot/internal/config"
+	"tinkoff-invest-bot/internal/strategy"
+	"tinkoff-invest-bot/pkg/sdk"
+)
+
+// PortfolioLimits кросс-инструментные ограничения, которые не может обеспечить
+// ни один отдельный микро-робот
+type PortfolioLimits struct {
+	// MaxNotional максимальная суммарная открытая позиция портфеля в валюте счёта
+	MaxNotional float64
+	// MaxPositionsPerExchange максимум одновременных открытых позиций на одной бирже,
+	// 0 означает отсутствие ограничения
+	MaxPositionsPerExchange int
+	// MaxDrawdown просадка эквити портфеля, при превышении которой срабатывает kill-switch
+	// и торговля останавливается во всех дочерних роботах, 0 означает отсутствие ограничения
+	MaxDrawdown float64
+}
+
+// openPosition открытая длинная позиция по одному инструменту, ждущая закрывающей продажи
+type openPosition struct {
+	exchange string
+	notional float64
+}
+
+// Portfolio владеет набором микро-роботов по разным инструментам и обеспечивает
+// кросс-инструментные риск-ограничения: лимит суммарной экспозиции, лимит одновременных
+// позиций на биржу и kill-switch по просадке портфеля. Намерения дочерних стратегий совершить
+// сделку проходят через общий strategy.EventBus, поэтому риск-проверки срабатывают до того,
+// как заявка будет фактически отправлена через SDK. Стратегии, загруженные из .so-плагина,
+// шину не получают, и их сделки риск-проверки портфеля не проходят
+type Portfolio struct {
+	robotConfig *config.RobotConfig
+	sdk         *sdk.SDK
+	logger      *zap.Logger
+	limits      PortfolioLimits
+	bus         *strategy.EventBus
+
+	mu             sync.Mutex
+	ctx            context.Context
+	wg             sync.WaitGroup
+	robots         map[string]*investRobot
+	cancels        map[string]context.CancelFunc
+	openPositions  map[string]openPosition // figi -> открытая позиция
+	openByExchange map[string]int
+	openNotional   float64
+	equity         float64
+	peakEquity     float64
+}
+
+// NewPortfolio создаёт пустой портфель с указанными риск-лимитами. Инструменты добавляются
+// через Add как до, так и после запуска Run
+func NewPortfolio(conf *config.RobotConfig, s *sdk.SDK, logger *zap.Logger, limits PortfolioLimits) *Portfolio {
+	p := &Portfolio{
+		robotConfig: conf,
+		sdk:         s,
+		logger:      logger,
+		limits:      limits,
+		bus:         strategy.NewEventBus(),
+
+		robots:         map[string]*investRobot{},
+		cancels:        map[string]context.CancelFunc{},
+		openPositions:  map[string]openPosition{},
+		openByExchange: map[string]int{},
+	}
+	p.bus.SetApprover(p.approve)
+	p.bus.Watch(p.onTrade)
+	return p
+}
+
+// Add создаёт и добавляет в портфель микро-робота по tradingConfig. Если Run уже запущен,
+// робот стартует немедленно в своей горутине, иначе будет запущен вместе с остальными при вызове Run
+func (p *Portfolio) Add(cfg *config.TradingConfig) error {
+	r, err := New(p.robotConfig, cfg, p.sdk, p.logger, p.bus)
+	if err != nil {
+		return xerrors.Errorf("can't create micro-robot for %s: %w", cfg.Ticker, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, exists := p.robots[cfg.Figi]; exists {
+		return xerrors.Errorf("instrument %s is already in the portfolio", cfg.Figi)
+	}
+	p.robots[cfg.Figi] = r
+
+	if p.ctx != nil {
+		p.startLocked(cfg.Figi, r)
+	}
+	return nil
+}
+
+// Remove останавливает и убирает микро-робота по figi из портфеля
+func (p *Portfolio) Remove(figi string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if cancel, ok := p.cancels[figi]; ok {
+		cancel()
+		delete(p.cancels, figi)
+	}
+	delete(p.robots, figi)
+}
+
+// Run запускает все добавленные к этому моменту микро-роботы, блокируется, пока не
+// отменится ctx, и дожидается, пока все дочерние роботы закончат разворачиваться, прежде
+// чем вернуть управление. Роботы, добавленные позже через Add, запускаются сразу по мере добавления
+func (p *Portfolio) Run(ctx context.Context) {
+	p.mu.Lock()
+	p.ctx = ctx
+	for figi, r := range p.robots {
+		p.startLocked(figi, r)
+	}
+	p.mu.Unlock()
+
+	<-ctx.Done()
+	p.wg.Wait()
+}
+
+// startLocked запускает робота figi в отдельной горутине с ctx, производным от контекста
+// портфеля; вызывающий обязан держать p.mu
+func (p *Portfolio) startLocked(figi string, r *investRobot) {
+	robotCtx, cancel := context.WithCancel(p.ctx)
+	p.cancels[figi] = cancel
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		r.Run(robotCtx)
+	}()
+}
+
+// approve риск-проверка портфеля перед отправкой заявки: лимит суммарной экспозиции
+// и лимит одновременных позиций на биржу. Оба лимита защищают от открытия новой
+// экспозиции, поэтому закрывающий Sell пропускается без проверок - иначе робот,
+// упёршийся в лимит, смог бы войти в позицию, но никогда не смог бы из неё выйти.
+// Регистрируется в EventBus как Approver
+func (p *Portfolio) approve(event strategy.TradeEvent) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if event.Side != strategy.SignalBuy {
+		return true
+	}
+
+	if p.limits.MaxNotional > 0 && p.openNotional+event.Notional > p.limits.MaxNotional {
+		p.logger.Info(
+			"Portfolio risk check rejected trade: max notional exceeded",
+			zap.String("figi", event.Figi),
+			zap.Float64("notional", event.Notional),
+		)
+		return false
+	}
+
+	// Лимит позиций на биржу считает именно открытие новой позиции: докупка по уже
+	// открытому инструменту не увеличивает число одновременных позиций на бирже
+	if _, alreadyOpen := p.openPositions[event.Figi]; !alreadyOpen {
+		if p.limits.MaxPositionsPerExchange > 0 && p.openByExchange[event.Exchange] >= p.limits.MaxPositionsPerExchange {
+			p.logger.Info(
+				"Portfolio risk check rejected trade: max concurrent positions per exchange exceeded",
+				zap.String("figi", event.Figi),
+				zap.String("exchange", event.Exchange),
+			)
+			return false
+		}
+	}
+
+	return true
+}
+
+// onTrade обновляет агрегированную экспозицию и реализованный PnL портфеля по одобренной
+// сделке и взводит kill-switch всех дочерних роботов, если просадка эквити превысила
+// MaxDrawdown. Регистрируется в EventBus как Watcher.
+//
+// По каждому инструменту одновременно держится не больше одной открытой позиции: Buy
+// открывает её, докупка увеличивает её notional, а закрывающий Sell реализует PnL как
+// разницу между notional продажи и суммарным notional покупок по позиции. Для одной покупки
+// и одной продажи это совпадает с round-trip PnL, который считает engine.Backtester
+func (p *Portfolio) onTrade(event strategy.TradeEvent) {
+	p.mu.Lock()
+
+	switch event.Side {
+	case strategy.SignalBuy:
+		position, open := p.openPositions[event.Figi]
+		if !open {
+			p.openByExchange[event.Exchange]++
+		}
+		position.exchange = event.Exchange
+		position.notional += event.Notional
+		p.openPositions[event.Figi] = position
+		p.openNotional += event.Notional
+	case strategy.SignalSell:
+		entry, open := p.openPositions[event.Figi]
+		if !open {
+			// Продажа без открытой позиции (short) портфелем не отслеживается: она не меняет
+			// ни экспозицию, ни реализованный PnL
+			break
+		}
+
+		realized := event.Notional - entry.notional
+		p.equity += realized
+		p.openNotional -= entry.notional
+		p.openByExchange[entry.exchange]--
+		delete(p.openPositions, event.Figi)
+	}
+
+	if p.equity > p.peakEquity {
+		p.peakEquity = p.equity
+	}
+	drawdown := p.peakEquity - p.equity
+	tripped := p.limits.MaxDrawdown > 0 && drawdown > p.limits.MaxDrawdown
+
+	robots := make([]*investRobot, 0, len(p.robots))
+	for _, r := range p.robots {
+		robots = append(robots, r)
+	}
+	p.mu.Unlock()
+
+	if !tripped {
+		return
+	}
+
+	p.logger.Info(
+		"Portfolio kill-switch triggered: drawdown exceeded limit, halting all robots",
+		zap.Float64("drawdown", drawdown),
+		zap.Float64("limit", p.limits.MaxDrawdown),
+	)
+	for _, r := range robots {
+		r.Halt()
+	}
+}
@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"tinkoff-invest-bot/internal/strategy"
+)
+
+func newTestPortfolio(limits PortfolioLimits) *Portfolio {
+	return &Portfolio{
+		logger:         zap.NewNop(),
+		limits:         limits,
+		robots:         map[string]*investRobot{},
+		cancels:        map[string]context.CancelFunc{},
+		openPositions:  map[string]openPosition{},
+		openByExchange: map[string]int{},
+	}
+}
+
+func TestPortfolioApprove_SellAlwaysAllowed(t *testing.T) {
+	p := newTestPortfolio(PortfolioLimits{MaxNotional: 100, MaxPositionsPerExchange: 1})
+	p.openNotional = 100
+	p.openByExchange["MOEX"] = 1
+
+	if !p.approve(strategy.TradeEvent{Figi: "FIGI1", Exchange: "MOEX", Side: strategy.SignalSell, Notional: 1000}) {
+		t.Error("closing sell should always be approved regardless of notional/position limits")
+	}
+}
+
+func TestPortfolioApprove_BuyRejectedOverNotional(t *testing.T) {
+	p := newTestPortfolio(PortfolioLimits{MaxNotional: 100})
+	p.openNotional = 60
+
+	if p.approve(strategy.TradeEvent{Figi: "FIGI1", Exchange: "MOEX", Side: strategy.SignalBuy, Notional: 50}) {
+		t.Error("buy exceeding MaxNotional should be rejected")
+	}
+}
+
+func TestPortfolioApprove_BuyRejectedOverPositionsPerExchange(t *testing.T) {
+	p := newTestPortfolio(PortfolioLimits{MaxPositionsPerExchange: 1})
+	p.openByExchange["MOEX"] = 1
+
+	if p.approve(strategy.TradeEvent{Figi: "FIGI-NEW", Exchange: "MOEX", Side: strategy.SignalBuy, Notional: 10}) {
+		t.Error("buy opening a new position past MaxPositionsPerExchange should be rejected")
+	}
+}
+
+func TestPortfolioApprove_BuyIntoAlreadyOpenPositionIgnoresPositionCap(t *testing.T) {
+	p := newTestPortfolio(PortfolioLimits{MaxPositionsPerExchange: 1})
+	p.openByExchange["MOEX"] = 1
+	p.openPositions["FIGI1"] = openPosition{exchange: "MOEX", notional: 10}
+
+	if !p.approve(strategy.TradeEvent{Figi: "FIGI1", Exchange: "MOEX", Side: strategy.SignalBuy, Notional: 10}) {
+		t.Error("adding to an already open position should not be blocked by MaxPositionsPerExchange")
+	}
+}
+
+func TestPortfolioOnTrade_RealizesPnLAndTripsKillSwitch(t *testing.T) {
+	p := newTestPortfolio(PortfolioLimits{MaxDrawdown: 5})
+	r := &investRobot{}
+	p.robots["FIGI1"] = r
+
+	p.onTrade(strategy.TradeEvent{Figi: "FIGI1", Exchange: "MOEX", Side: strategy.SignalBuy, Notional: 100})
+	if p.openByExchange["MOEX"] != 1 {
+		t.Fatalf("buy should open a position, openByExchange = %d", p.openByExchange["MOEX"])
+	}
+
+	p.onTrade(strategy.TradeEvent{Figi: "FIGI1", Exchange: "MOEX", Side: strategy.SignalSell, Notional: 90})
+
+	if p.equity != -10 {
+		t.Fatalf("realized PnL should be sell notional minus buy notional, equity = %v", p.equity)
+	}
+	if p.openByExchange["MOEX"] != 0 {
+		t.Errorf("closing sell should decrement openByExchange, got %d", p.openByExchange["MOEX"])
+	}
+	if _, open := p.openPositions["FIGI1"]; open {
+		t.Error("closed position should be removed from openPositions")
+	}
+	if !r.halted.Load() {
+		t.Error("drawdown of 10 over MaxDrawdown 5 should halt every robot in the portfolio")
+	}
+}
+
+func TestPortfolioOnTrade_UnmatchedSellDoesNotGoNegative(t *testing.T) {
+	p := newTestPortfolio(PortfolioLimits{})
+
+	p.onTrade(strategy.TradeEvent{Figi: "FIGI1", Exchange: "MOEX", Side: strategy.SignalSell, Notional: 100})
+
+	if p.openByExchange["MOEX"] != 0 {
+		t.Errorf("unmatched sell must not decrement openByExchange below zero, got %d", p.openByExchange["MOEX"])
+	}
+}
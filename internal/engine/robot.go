@@ -1,7 +1,10 @@
 package engine
 
 import (
+	"context"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
@@ -20,12 +23,32 @@ type investRobot struct {
 	logger          *zap.Logger
 	sdk             *sdk.SDK
 
+	// restartDelay задержка переподписки на стрим свечей и периодичность опроса halted;
+	// интервал между попытками запуска run() считает restartScheduler
 	restartDelay time.Duration
+
+	// halted выставляется портфелем (engine.Portfolio) как kill-switch: пока флаг взведён,
+	// робот останавливает запущенную стратегию, не запускает новую и ждёт снятия флага
+	halted atomic.Bool
+
+	statusMu   sync.Mutex
+	state      string
+	nextWakeAt time.Time
+}
+
+// Status текущее состояние микро-робота для отображения в операторском UI,
+// например "sleeping until 10:00 MSK" вместо общего сообщения о повторной попытке
+type Status struct {
+	Ticker     string
+	State      string
+	NextWakeAt time.Time
 }
 
-// New создать новый инстанс микро-робота
-func New(conf *config.RobotConfig, tradingConfig *config.TradingConfig, s *sdk.SDK, logger *zap.Logger) (*investRobot, error) {
-	tradingStrategy, err := strategy.FromConfig(tradingConfig, s, logger)
+// New создать новый инстанс микро-робота. bus — необязательная шина событий портфеля
+// (см. engine.Portfolio), через которую стратегия согласовывает сделки с риск-менеджером;
+// для одиночного робота, запускаемого не через Portfolio, передайте nil
+func New(conf *config.RobotConfig, tradingConfig *config.TradingConfig, s *sdk.SDK, logger *zap.Logger, bus *strategy.EventBus) (*investRobot, error) {
+	tradingStrategy, err := strategy.FromConfig(tradingConfig, s, logger, bus)
 	if err != nil {
 		return nil, err
 	}
@@ -53,54 +76,235 @@ func New(conf *config.RobotConfig, tradingConfig *config.TradingConfig, s *sdk.S
 		sdk:             s,
 
 		restartDelay: 10 * time.Second,
+		state:        "running",
 	}, nil
 }
 
-// Run запускает микро-робота,
-// микро-робот будет автоматически перезапускаться в случае ошибки
-func (r *investRobot) Run() {
+// Halt взводит kill-switch портфеля: робот останавливает запущенную стратегию и не запускает
+// её снова до вызова Resume
+func (r *investRobot) Halt() {
+	r.halted.Store(true)
+}
+
+// Resume снимает kill-switch, взведённый Halt
+func (r *investRobot) Resume() {
+	r.halted.Store(false)
+}
+
+// Status возвращает текущее состояние микро-робота и момент следующего пробуждения,
+// если он сейчас спит в ожидании открытия биржи или backoff-паузы после ошибки
+func (r *investRobot) Status() Status {
+	r.statusMu.Lock()
+	defer r.statusMu.Unlock()
+
+	return Status{
+		Ticker:     r.tradingConfig.Ticker,
+		State:      r.state,
+		NextWakeAt: r.nextWakeAt,
+	}
+}
+
+func (r *investRobot) setStatus(state string, nextWakeAt time.Time) {
+	r.statusMu.Lock()
+	defer r.statusMu.Unlock()
+
+	r.state = state
+	r.nextWakeAt = nextWakeAt
+}
+
+// Run запускает микро-робота, который будет автоматически перезапускаться в случае ошибки,
+// пока не отменится переданный ctx — тогда Run корректно завершает работу и возвращается
+func (r *investRobot) Run(ctx context.Context) {
+	scheduler := newRestartScheduler(r.sdk)
+
 	for {
+		if ctx.Err() != nil {
+			r.logger.Info(
+				"Micro-robot stopped",
+				zap.String("ticker", r.tradingConfig.Figi),
+			)
+			return
+		}
+
+		if r.halted.Load() {
+			r.setStatus("halted", time.Time{})
+			select {
+			case <-ctx.Done():
+				continue
+			case <-time.After(r.restartDelay):
+			}
+			continue
+		}
+
+		r.setStatus("running", time.Time{})
 		r.logger.Info(
 			"Micro-robot started",
 			zap.String("ticker", r.tradingConfig.Figi),
 		)
 
-		if err := r.run(); err != nil {
+		err := r.run(ctx)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			// ctx отменён, run() вернулась с ошибкой как побочным эффектом отмены — это не сбой
+		case err != nil:
 			r.logger.Info(
 				"Micro-robot finished with error",
 				zap.String("ticker", r.tradingConfig.Figi),
 				zap.Error(err),
 			)
-		} else {
+		default:
 			r.logger.Info(
 				"Micro-robot finished successfully",
 				zap.String("ticker", r.tradingConfig.Figi),
 			)
 		}
 
-		time.Sleep(r.restartDelay)
+		nextWakeAt := scheduler.next(r.tradingConfig.Exchange, err)
+		r.setStatus("sleeping", nextWakeAt)
+		r.logger.Info(
+			"Micro-robot sleeping",
+			zap.String("ticker", r.tradingConfig.Figi),
+			zap.Time("nextWakeAt", nextWakeAt),
+		)
+
+		select {
+		case <-ctx.Done():
+			continue
+		case <-time.After(time.Until(nextWakeAt)):
+		}
 	}
 }
 
-func (r *investRobot) run() error {
-	canTrade, _, err := r.sdk.CanTradeNow(r.tradingConfig.Exchange)
+func (r *investRobot) run(ctx context.Context) error {
+	canTrade, _, err := r.sdk.CanTradeNow(ctx, r.tradingConfig.Exchange)
 	if err != nil {
 		return xerrors.Errorf("can't receive trading schedules: %w", err)
 	}
 	if !canTrade {
-		return xerrors.Errorf("instrument %s is not available, exchange is closed", r.tradingConfig.Ticker)
+		return &exchangeClosedError{exchange: r.tradingConfig.Exchange}
 	}
 
-	err = (*r.tradingStrategy).Start()
+	err = (*r.tradingStrategy).Start(ctx)
 	if err != nil {
 		return xerrors.Errorf("can't start robot trading strategy, %v", err)
 	}
 
-	(*r.tradingStrategy).BlockUntilEnd()
+	stop, err := r.streamCandles(ctx)
+	if err != nil {
+		return xerrors.Errorf("can't subscribe to candles stream: %w", err)
+	}
+	defer stop()
+
+	// BlockUntilEnd обязана вернуться по отмене ctx, но подстраховываемся на случай
+	// конкретной реализации стратегии, которая этот контракт не соблюдает: ждём либо
+	// её естественного завершения, либо отмены ctx, чтобы run() не зависла в SIGINT
+	blockEnded := make(chan struct{})
+	go func() {
+		defer close(blockEnded)
+		(*r.tradingStrategy).BlockUntilEnd(ctx)
+	}()
+
+	// Kill-switch портфеля (Halt) прерывает и уже запущенную стратегию: флаг halted
+	// опрашивается с периодичностью restartDelay
+	haltCheck := time.NewTicker(r.restartDelay)
+	defer haltCheck.Stop()
 
-	err = (*r.tradingStrategy).Stop()
+wait:
+	for {
+		select {
+		case <-blockEnded:
+			break wait
+		case <-ctx.Done():
+			break wait
+		case <-haltCheck.C:
+			if r.halted.Load() {
+				break wait
+			}
+		}
+	}
+
+	err = (*r.tradingStrategy).Stop(ctx)
 	if err != nil {
 		return xerrors.Errorf("can't stop robot trading strategy, %v", err)
 	}
 	return nil
 }
+
+// streamCandles подписывается на MarketDataStream и скармливает входящие свечки стратегии,
+// автоматически переподписываясь с задержкой restartDelay, если стрим оборвался.
+// Отменённый ctx останавливает подписку и приводит к выходу из BlockUntilEnd.
+// Подписка (и её unsubscribe) целиком живёт в горутине, обновляющей её на переподписке;
+// вызывающий получает наружу только done-канал, чтобы не делить состояние между горутинами
+func (r *investRobot) streamCandles(ctx context.Context) (func(), error) {
+	interval := sdk.IntervalToCandleInterval(r.tradingConfig.StrategyConfig.Interval)
+
+	candles, unsubscribe, err := r.sdk.SubscribeCandles(ctx, r.tradingConfig.Figi, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer func() {
+			if err := unsubscribe(); err != nil {
+				r.logger.Info(
+					"Can't unsubscribe from candles stream",
+					zap.String("ticker", r.tradingConfig.Ticker),
+					zap.Error(err),
+				)
+			}
+		}()
+
+		for {
+			select {
+			case candle, ok := <-candles:
+				if !ok {
+					r.logger.Info(
+						"Candles stream closed, re-subscribing",
+						zap.String("ticker", r.tradingConfig.Ticker),
+						zap.Duration("delay", r.restartDelay),
+					)
+
+					select {
+					case <-time.After(r.restartDelay):
+					case <-ctx.Done():
+						return
+					case <-done:
+						return
+					}
+
+					nextCandles, nextUnsubscribe, err := r.sdk.SubscribeCandles(ctx, r.tradingConfig.Figi, interval)
+					if err != nil {
+						r.logger.Info(
+							"Can't re-subscribe to candles stream",
+							zap.String("ticker", r.tradingConfig.Ticker),
+							zap.Error(err),
+						)
+						// candles остаётся закрытым, поэтому следующая итерация снова
+						// выждет restartDelay и повторит попытку
+						continue
+					}
+					candles, unsubscribe = nextCandles, nextUnsubscribe
+					continue
+				}
+
+				techanCandle := strategy.StreamCandleToTechanCandle(candle)
+				if _, err := (*r.tradingStrategy).Feed(techanCandle); err != nil {
+					r.logger.Info(
+						"Strategy failed to process streamed candle",
+						zap.String("ticker", r.tradingConfig.Ticker),
+						zap.Error(err),
+					)
+				}
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+	}, nil
+}
@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"errors"
+	"time"
+
+	"tinkoff-invest-bot/pkg/sdk"
+)
+
+const (
+	// minRestartBackoff начальная задержка перезапуска после транзиентной ошибки
+	minRestartBackoff = 100 * time.Millisecond
+	// maxRestartBackoff потолок экспоненциального backoff и запасная задержка,
+	// если расписание торгов получить не удалось
+	maxRestartBackoff = 30 * time.Second
+)
+
+// exchangeClosedError помечает ошибку run() как "биржа сейчас закрыта", а не транзиентный сбой,
+// чтобы restartScheduler не наращивал backoff, а точно проспал до следующей сессии
+type exchangeClosedError struct {
+	exchange string
+}
+
+func (e *exchangeClosedError) Error() string {
+	return "exchange " + e.exchange + " is closed"
+}
+
+// restartScheduler считает, сколько ждать перед следующей попыткой run(): если биржа
+// закрыта, он досыпает точно до открытия следующей торговой сессии по расписанию
+// sdk.GetTradingSchedules, иначе использует экспоненциальный backoff с потолком
+type restartScheduler struct {
+	sdk     *sdk.SDK
+	backoff time.Duration
+}
+
+func newRestartScheduler(s *sdk.SDK) *restartScheduler {
+	return &restartScheduler{sdk: s, backoff: minRestartBackoff}
+}
+
+// next возвращает момент времени, до которого нужно проспать, учитывая причину runErr
+// (nil, если run() завершилась успешно). Успешное завершение и закрытая биржа сбрасывают
+// backoff к минимуму - он растёт только пока run() подряд падает с транзиентными ошибками
+func (sch *restartScheduler) next(exchange string, runErr error) time.Time {
+	if runErr == nil {
+		// run() отработала чисто - это не сбой, backoff сбрасывается и не ждём лишнего
+		sch.backoff = minRestartBackoff
+		return time.Now()
+	}
+
+	var closedErr *exchangeClosedError
+	if errors.As(runErr, &closedErr) {
+		// Биржа закрыта - это ожидаемое состояние, а не сбой, backoff не растёт
+		sch.backoff = minRestartBackoff
+
+		schedule, err := sch.sdk.GetTradingSchedules(exchange)
+		if err != nil {
+			return time.Now().Add(maxRestartBackoff)
+		}
+		if nextOpen, ok := schedule.NextOpen(time.Now()); ok {
+			return nextOpen
+		}
+		return time.Now().Add(maxRestartBackoff)
+	}
+
+	wake := time.Now().Add(sch.backoff)
+
+	sch.backoff *= 2
+	if sch.backoff > maxRestartBackoff {
+		sch.backoff = maxRestartBackoff
+	}
+
+	return wake
+}
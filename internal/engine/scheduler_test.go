@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+func TestRestartSchedulerNext_CleanRun(t *testing.T) {
+	sch := &restartScheduler{backoff: 10 * time.Second}
+
+	before := time.Now()
+	wake := sch.next("MOEX", nil)
+
+	if wake.Before(before) || wake.After(time.Now()) {
+		t.Errorf("clean run should wake immediately, got %v (before %v)", wake, before)
+	}
+	if sch.backoff != minRestartBackoff {
+		t.Errorf("clean run should reset backoff to %v, got %v", minRestartBackoff, sch.backoff)
+	}
+}
+
+func TestRestartSchedulerNext_TransientErrorGrowsAndCaps(t *testing.T) {
+	sch := &restartScheduler{backoff: minRestartBackoff}
+	transientErr := xerrors.New("connection reset")
+
+	var lastBackoff time.Duration
+	for i := 0; i < 10; i++ {
+		before := time.Now()
+		wake := sch.next("MOEX", transientErr)
+		wait := wake.Sub(before)
+
+		if wait < 0 {
+			t.Fatalf("iteration %d: wake %v is before call time %v", i, wake, before)
+		}
+		if sch.backoff < lastBackoff {
+			t.Fatalf("iteration %d: backoff shrank from %v to %v", i, lastBackoff, sch.backoff)
+		}
+		lastBackoff = sch.backoff
+	}
+
+	if sch.backoff != maxRestartBackoff {
+		t.Errorf("backoff should have climbed to the cap %v, got %v", maxRestartBackoff, sch.backoff)
+	}
+}
+
+func TestRestartSchedulerNext_TransientErrorAfterCleanRunStartsOverAtMin(t *testing.T) {
+	sch := &restartScheduler{backoff: maxRestartBackoff}
+
+	sch.next("MOEX", nil)
+	if sch.backoff != minRestartBackoff {
+		t.Fatalf("clean run should reset backoff, got %v", sch.backoff)
+	}
+
+	sch.next("MOEX", xerrors.New("boom"))
+	if sch.backoff <= minRestartBackoff {
+		t.Errorf("backoff should grow again after a fresh transient error, got %v", sch.backoff)
+	}
+}
@@ -0,0 +1,22 @@
+package strategy
+
+import (
+	"github.com/sdcoffey/techan"
+
+	"tinkoff-invest-bot/pkg/sdk"
+)
+
+// StreamCandleToTechanCandle конвертирует свечку, полученную из MarketDataStream,
+// в формат библиотеки techan, ожидаемый CandlesStrategyProcessor.Feed
+func StreamCandleToTechanCandle(candle sdk.Candle) techan.Candle {
+	period := techan.NewTimePeriod(candle.Time, 0)
+
+	techanCandle := techan.NewCandle(period)
+	techanCandle.OpenPrice = techan.NewDecimal(candle.Open)
+	techanCandle.MaxPrice = techan.NewDecimal(candle.High)
+	techanCandle.MinPrice = techan.NewDecimal(candle.Low)
+	techanCandle.ClosePrice = techan.NewDecimal(candle.Close)
+	techanCandle.Volume = techan.NewDecimal(float64(candle.Volume))
+
+	return *techanCandle
+}
@@ -0,0 +1,60 @@
+package strategy
+
+import "sync"
+
+// TradeEvent описывает сделку (реальную или намеченную) одного из инструментов,
+// управляемых общим портфелем
+type TradeEvent struct {
+	Figi     string
+	Exchange string
+	Side     Signal
+	Notional float64
+}
+
+// EventBus агрегирует торговые события дочерних инвест-роботов общего портфеля.
+// Перед фактической отправкой заявки через SDK стратегия обязана вызвать RequestTrade,
+// чтобы риск-менеджер портфеля успел одобрить или отклонить сделку
+type EventBus struct {
+	mu       sync.Mutex
+	approve  func(TradeEvent) bool
+	watchers []func(TradeEvent)
+}
+
+// NewEventBus создаёт пустую шину событий без риск-проверок
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// SetApprover задаёт риск-проверку портфеля, вызываемую на каждый RequestTrade.
+// Отсутствие проверки (nil) означает, что все сделки одобряются
+func (b *EventBus) SetApprover(approve func(TradeEvent) bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.approve = approve
+}
+
+// Watch подписывается на поток одобренных сделок, например для агрегации аналитики портфеля
+func (b *EventBus) Watch(fn func(TradeEvent)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.watchers = append(b.watchers, fn)
+}
+
+// RequestTrade прогоняет намеченную сделку через риск-проверку портфеля и, если она одобрена,
+// уведомляет подписчиков. Стратегия должна отправлять заявку через SDK только если approved == true
+func (b *EventBus) RequestTrade(event TradeEvent) (approved bool) {
+	b.mu.Lock()
+	approve := b.approve
+	watchers := append([]func(TradeEvent){}, b.watchers...)
+	b.mu.Unlock()
+
+	approved = approve == nil || approve(event)
+	if !approved {
+		return false
+	}
+
+	for _, watch := range watchers {
+		watch(event)
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+package strategy
+
+import (
+	"plugin"
+
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+
+	"tinkoff-invest-bot/internal/config"
+	"tinkoff-invest-bot/pkg/sdk"
+)
+
+// pluginSymbol имя функции, которую обязан экспортировать .so с пользовательской стратегией
+const pluginSymbol = "NewStrategy"
+
+// builtinStrategies реестр встроенных свечных стратегий; каждая встроенная стратегия
+// регистрирует себя в этой мапе по имени из StrategyConfig.Name.
+//
+// Реестр пока пуст: эта серия запросов добавляет только механизм загрузки стратегий
+// из .so-плагинов (см. strategyFromPlugin), сами встроенные стратегии не входят в её
+// объём и будут регистрироваться сюда отдельными запросами. До тех пор StrategyConfig
+// без PluginPath всегда приводит к ошибке в FromConfig
+var builtinStrategies = map[string]func(cfg map[string]any, s *sdk.SDK, logger *zap.Logger, bus *EventBus) (CandlesStrategyProcessor, error){}
+
+// FromConfig создаёт торговую стратегию по StrategyConfig. Если задан StrategyConfig.PluginPath,
+// стратегия загружается из скомпилированного Go-плагина через plugin.Open, иначе берётся
+// встроенная стратегия по StrategyConfig.Name. Это позволяет поставлять свои стратегии
+// без форка модуля.
+//
+// bus — необязательная шина событий портфеля (см. engine.Portfolio); если она не nil,
+// встроенные стратегии обязаны согласовывать каждую сделку через bus.RequestTrade,
+// прежде чем отправить заявку через SDK. Для стратегий из плагина риск-проверки портфеля
+// недоступны — контракт плагина фиксирован и не расширяется
+func FromConfig(tradingConfig *config.TradingConfig, s *sdk.SDK, logger *zap.Logger, bus *EventBus) (*CandlesStrategyProcessor, error) {
+	cfg := tradingConfig.StrategyConfig
+
+	if cfg.PluginPath != "" {
+		strat, err := strategyFromPlugin(cfg.PluginPath, cfg.Params, s, logger)
+		if err != nil {
+			return nil, xerrors.Errorf("can't load strategy plugin %s: %w", cfg.PluginPath, err)
+		}
+		return &strat, nil
+	}
+
+	newStrategy, ok := builtinStrategies[cfg.Name]
+	if !ok {
+		return nil, xerrors.Errorf("unknown strategy %q: no built-in strategies are registered yet, set StrategyConfig.PluginPath instead", cfg.Name)
+	}
+
+	strat, err := newStrategy(cfg.Params, s, logger, bus)
+	if err != nil {
+		return nil, xerrors.Errorf("can't build strategy %q: %w", cfg.Name, err)
+	}
+	return &strat, nil
+}
+
+// strategyFromPlugin открывает .so по path и резолвит символ NewStrategy вида
+// func(cfg map[string]any, s *sdk.SDK, logger *zap.Logger) (strategy.CandlesStrategyProcessor, error)
+func strategyFromPlugin(path string, cfg map[string]any, s *sdk.SDK, logger *zap.Logger) (CandlesStrategyProcessor, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, xerrors.Errorf("can't open plugin: %w", err)
+	}
+
+	sym, err := p.Lookup(pluginSymbol)
+	if err != nil {
+		return nil, xerrors.Errorf("plugin doesn't export %s: %w", pluginSymbol, err)
+	}
+
+	newStrategy, ok := sym.(func(cfg map[string]any, s *sdk.SDK, logger *zap.Logger) (CandlesStrategyProcessor, error))
+	if !ok {
+		return nil, xerrors.Errorf("plugin symbol %s has unexpected signature", pluginSymbol)
+	}
+
+	return newStrategy(cfg, s, logger)
+}
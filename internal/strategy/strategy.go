@@ -0,0 +1,43 @@
+package strategy
+
+import (
+	"context"
+
+	"github.com/sdcoffey/techan"
+)
+
+// Signal синтетический торговый сигнал, который стратегия возвращает по результатам
+// обработки очередной свечи, не размещая реальную заявку в стакане
+type Signal int
+
+const (
+	// SignalHold стратегия не видит повода для сделки
+	SignalHold Signal = iota
+	// SignalBuy стратегия сигнализирует о покупке
+	SignalBuy
+	// SignalSell стратегия сигнализирует о продаже
+	SignalSell
+)
+
+// CandlesStrategyProcessor интерфейс торговой стратегии, принимающей решения по свечным данным
+type CandlesStrategyProcessor interface {
+	// Init прогревает стратегию историческими свечками перед первым запуском
+	Init(series *techan.TimeSeries)
+
+	// Start запускает обработку live-потока свечей и размещение заявок.
+	// Отменённый ctx должен прервать запуск без зависания
+	Start(ctx context.Context) error
+
+	// Stop останавливает обработку. Отменённый ctx не освобождает от вызова Stop —
+	// он лишь ограничивает время ожидания корректной остановки
+	Stop(ctx context.Context) error
+
+	// BlockUntilEnd блокирует вызывающего до завершения работы стратегии и обязана
+	// вернуться, как только ctx отменяется, даже если стратегия ещё не завершилась сама
+	BlockUntilEnd(ctx context.Context)
+
+	// Feed скармливает стратегии одну свечу и возвращает синтетический сигнал без
+	// реального выставления заявки. Используется офлайн-прогоном (см. engine.Backtester),
+	// чтобы можно было подбирать параметры StrategyConfig, не торгуя вживую
+	Feed(candle techan.Candle) (Signal, error)
+}
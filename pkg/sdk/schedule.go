@@ -0,0 +1,62 @@
+package sdk
+
+import (
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// TradingSession один сессионный интервал торгов на бирже
+type TradingSession struct {
+	Open  time.Time
+	Close time.Time
+}
+
+// TradingSchedule расписание торговых сессий биржи на ближайшие дни
+type TradingSchedule struct {
+	Exchange string
+	Sessions []TradingSession
+}
+
+// NextOpen возвращает время открытия ближайшей сессии после now и true, если в расписании
+// есть ещё не начавшаяся сессия
+func (t *TradingSchedule) NextOpen(now time.Time) (time.Time, bool) {
+	for _, session := range t.Sessions {
+		if session.Open.After(now) {
+			return session.Open, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// tradingScheduleLookahead горизонт, на который запрашивается расписание торгов за один вызов
+const tradingScheduleLookahead = 7 * 24 * time.Hour
+
+// GetTradingSchedules запрашивает у Tinkoff Invest API расписание торговых сессий exchange
+// на ближайшую неделю
+func (s *SDK) GetTradingSchedules(exchange string) (*TradingSchedule, error) {
+	now := time.Now()
+
+	resp, err := s.client.Instruments.TradingSchedules(exchange, now, now.Add(tradingScheduleLookahead))
+	if err != nil {
+		return nil, xerrors.Errorf("can't get trading schedules for %s: %w", exchange, err)
+	}
+
+	schedule := &TradingSchedule{Exchange: exchange}
+	for _, ex := range resp.GetExchanges() {
+		if ex.GetExchange() != exchange {
+			continue
+		}
+		for _, day := range ex.GetDays() {
+			if !day.GetIsTradingDay() {
+				continue
+			}
+			schedule.Sessions = append(schedule.Sessions, TradingSession{
+				Open:  day.GetStartTime().AsTime(),
+				Close: day.GetEndTime().AsTime(),
+			})
+		}
+	}
+
+	return schedule, nil
+}
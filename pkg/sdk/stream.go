@@ -0,0 +1,60 @@
+package sdk
+
+import (
+	"context"
+	"time"
+
+	investapi "github.com/tinkoff/invest-api-go-sdk/investgo"
+	"golang.org/x/xerrors"
+)
+
+// Candle одна свечка, полученная через MarketDataStreamService в реальном времени
+type Candle struct {
+	Time   time.Time
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	Volume int64
+}
+
+// SubscribeCandles открывает подписку MarketDataStreamService на свечки инструмента figi
+// с шагом interval и возвращает канал с входящими свечками и функцию отписки.
+// В отличие от GetCandles подписка живёт до явной отписки или обрыва стрима.
+// Отменённый ctx прерывает пересылку свечек в candles, даже если её никто не читает,
+// иначе stream.Stop() не разбудит горутину, зависшую на отправке без получателя
+func (s *SDK) SubscribeCandles(ctx context.Context, figi string, interval CandleInterval) (<-chan Candle, func() error, error) {
+	stream, err := s.client.MarketDataStream.MarketDataStream()
+	if err != nil {
+		return nil, nil, xerrors.Errorf("can't open market data stream: %w", err)
+	}
+
+	if err := stream.SubscribeCandle([]string{figi}, interval); err != nil {
+		return nil, nil, xerrors.Errorf("can't subscribe to candles for %s: %w", figi, err)
+	}
+
+	candles := make(chan Candle)
+	go func() {
+		defer close(candles)
+		for event := range stream.Candles() {
+			select {
+			case candles <- candleFromStreamEvent(event):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return candles, stream.Stop, nil
+}
+
+func candleFromStreamEvent(event *investapi.Candle) Candle {
+	return Candle{
+		Time:   event.GetTime().AsTime(),
+		Open:   event.GetOpen().ToFloat(),
+		High:   event.GetHigh().ToFloat(),
+		Low:    event.GetLow().ToFloat(),
+		Close:  event.GetClose().ToFloat(),
+		Volume: event.GetVolume(),
+	}
+}